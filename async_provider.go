@@ -0,0 +1,212 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultAsyncBufferSize = 1024
+
+type asyncMethod int
+
+const (
+	asyncLog asyncMethod = iota
+	asyncError
+	asyncFatal
+	asyncDebug
+)
+
+type asyncItem struct {
+	method asyncMethod
+	msg    []byte
+	ctx    context.Context
+}
+
+// ContextProvider is implemented by providers that can abort a message
+// still sitting in the queue when a caller-supplied context.Context is
+// cancelled — as opposed to AsyncProvider.Context(), which only ever
+// reflects the provider's own process-wide shutdown via Close.
+// AsyncProvider implements this so a Logger carrying a request-scoped
+// context (see Logger.WithContext) can let a cancelled request's
+// messages be dropped instead of delivered, without waiting for the
+// whole provider to shut down.
+type ContextProvider interface {
+	LogCtx(ctx context.Context, msg []byte)
+	ErrorCtx(ctx context.Context, msg []byte)
+	FatalCtx(ctx context.Context, msg []byte)
+	DebugCtx(ctx context.Context, msg []byte)
+}
+
+// AsyncProvider wraps any ProviderInterface with a bounded queue drained
+// by a single background worker, so a slow or failing sink (Telegram,
+// email, a webhook) can't block the caller or leak a goroutine per
+// message. When the queue is full, messages are dropped and counted
+// instead of blocking the logger.
+type AsyncProvider struct {
+	id        string
+	inner     ProviderInterface
+	queue     chan asyncItem
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	dropped   uint64
+	aborted   uint64
+	closeOnce sync.Once
+
+	// mu guards closed: enqueue holds it for reading while it sends on
+	// queue, and Close holds it for writing before closing queue, so a
+	// send can never race a close of the same channel.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncProvider starts the background worker and returns a provider
+// that can be registered with Logger just like inner. size is the
+// queue's capacity; size <= 0 uses a default of 1024.
+func NewAsyncProvider(inner ProviderInterface, size int) *AsyncProvider {
+	if size <= 0 {
+		size = defaultAsyncBufferSize
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &AsyncProvider{
+		id:     inner.GetID(),
+		inner:  inner,
+		queue:  make(chan asyncItem, size),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	p.wg.Add(1)
+	go p.run()
+
+	return p
+}
+
+func (p *AsyncProvider) GetID() string { return p.id }
+
+// Context is cancelled once Close is called, so a wrapped provider can
+// abort an in-flight retry loop instead of waiting out its backoff.
+func (p *AsyncProvider) Context() context.Context { return p.ctx }
+
+func (p *AsyncProvider) Log(msg []byte)   { p.enqueue(asyncItem{method: asyncLog, msg: msg}) }
+func (p *AsyncProvider) Error(msg []byte) { p.enqueue(asyncItem{method: asyncError, msg: msg}) }
+func (p *AsyncProvider) Fatal(msg []byte) { p.enqueue(asyncItem{method: asyncFatal, msg: msg}) }
+func (p *AsyncProvider) Debug(msg []byte) { p.enqueue(asyncItem{method: asyncDebug, msg: msg}) }
+
+// LogCtx, ErrorCtx, FatalCtx and DebugCtx are the ContextProvider
+// counterparts of Log/Error/Fatal/Debug: the message is dropped (and
+// counted in Aborted) instead of delivered if ctx is already done by
+// the time the worker reaches it.
+func (p *AsyncProvider) LogCtx(ctx context.Context, msg []byte) {
+	p.enqueue(asyncItem{method: asyncLog, msg: msg, ctx: ctx})
+}
+
+func (p *AsyncProvider) ErrorCtx(ctx context.Context, msg []byte) {
+	p.enqueue(asyncItem{method: asyncError, msg: msg, ctx: ctx})
+}
+
+func (p *AsyncProvider) FatalCtx(ctx context.Context, msg []byte) {
+	p.enqueue(asyncItem{method: asyncFatal, msg: msg, ctx: ctx})
+}
+
+func (p *AsyncProvider) DebugCtx(ctx context.Context, msg []byte) {
+	p.enqueue(asyncItem{method: asyncDebug, msg: msg, ctx: ctx})
+}
+
+func (p *AsyncProvider) enqueue(item asyncItem) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		atomic.AddUint64(&p.dropped, 1)
+		return
+	}
+
+	select {
+	case p.queue <- item:
+	default:
+		atomic.AddUint64(&p.dropped, 1)
+	}
+}
+
+// Dropped returns the number of messages discarded because the queue
+// was full or Close had already been called.
+func (p *AsyncProvider) Dropped() uint64 {
+	return atomic.LoadUint64(&p.dropped)
+}
+
+// Aborted returns the number of messages discarded because their
+// per-message context (see LogCtx and friends) was already done by the
+// time the worker reached them.
+func (p *AsyncProvider) Aborted() uint64 {
+	return atomic.LoadUint64(&p.aborted)
+}
+
+// Close stops accepting new messages, lets the worker drain whatever is
+// already queued and waits for it to exit. It is safe to call more than
+// once. Holding mu for the whole close means any enqueue already in
+// flight finishes its send (or drop) before the channel closes, and any
+// enqueue that starts afterwards observes closed and never touches the
+// channel at all — without this, a concurrent enqueue could race the
+// close and panic with "send on closed channel".
+func (p *AsyncProvider) Close() {
+	p.closeOnce.Do(func() {
+		p.cancel()
+
+		p.mu.Lock()
+		p.closed = true
+		close(p.queue)
+		p.mu.Unlock()
+	})
+	p.wg.Wait()
+}
+
+func (p *AsyncProvider) run() {
+	defer p.wg.Done()
+
+	for item := range p.queue {
+		p.deliver(item)
+	}
+}
+
+func (p *AsyncProvider) deliver(item asyncItem) {
+	if item.ctx != nil {
+		select {
+		case <-item.ctx.Done():
+			atomic.AddUint64(&p.aborted, 1)
+			return
+		default:
+		}
+
+		// Pass ctx on to the inner provider when it can use it, so a
+		// retry/backoff loop already under way (telegramSender,
+		// syslogSender) also aborts on this message's own context being
+		// done, not just on the provider's process-wide shutdown.
+		if cp, ok := p.inner.(ContextProvider); ok {
+			switch item.method {
+			case asyncError:
+				cp.ErrorCtx(item.ctx, item.msg)
+			case asyncFatal:
+				cp.FatalCtx(item.ctx, item.msg)
+			case asyncDebug:
+				cp.DebugCtx(item.ctx, item.msg)
+			default:
+				cp.LogCtx(item.ctx, item.msg)
+			}
+			return
+		}
+	}
+
+	switch item.method {
+	case asyncError:
+		p.inner.Error(item.msg)
+	case asyncFatal:
+		p.inner.Fatal(item.msg)
+	case asyncDebug:
+		p.inner.Debug(item.msg)
+	default:
+		p.inner.Log(item.msg)
+	}
+}
@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingProvider lets a test hold the worker goroutine inside Log
+// until the test says to release it, so the queue can be driven full
+// on purpose.
+type blockingProvider struct {
+	release chan struct{}
+	mu      sync.Mutex
+	got     [][]byte
+}
+
+func (p *blockingProvider) GetID() string { return "blocking" }
+func (p *blockingProvider) Log(msg []byte) {
+	<-p.release
+	p.mu.Lock()
+	p.got = append(p.got, msg)
+	p.mu.Unlock()
+}
+func (p *blockingProvider) Error(msg []byte) {}
+func (p *blockingProvider) Fatal(msg []byte) {}
+func (p *blockingProvider) Debug(msg []byte) {}
+
+func TestAsyncProviderDropsWhenQueueFull(t *testing.T) {
+	inner := &blockingProvider{release: make(chan struct{})}
+	p := NewAsyncProvider(inner, 1)
+	defer func() {
+		close(inner.release)
+		p.Close()
+	}()
+
+	// The worker holds at most one item while it blocks delivering it,
+	// and the size-1 queue holds one more, so at most two of the three
+	// Logs below find room and at least one has nowhere to go but
+	// Dropped.
+	p.Log([]byte("first"))
+	p.Log([]byte("second"))
+	p.Log([]byte("third"))
+
+	if got := p.Dropped(); got == 0 {
+		t.Errorf("expected at least one dropped message, got %d", got)
+	}
+}
+
+func TestAsyncProviderCloseDrainsQueue(t *testing.T) {
+	inner := &blockingProvider{release: make(chan struct{})}
+	close(inner.release) // never actually blocks in this test
+
+	p := NewAsyncProvider(inner, 8)
+	for i := 0; i < 5; i++ {
+		p.Log([]byte("msg"))
+	}
+	p.Close()
+
+	inner.mu.Lock()
+	defer inner.mu.Unlock()
+	if len(inner.got) != 5 {
+		t.Errorf("expected Close to drain all 5 queued messages, got %d", len(inner.got))
+	}
+}
+
+func TestAsyncProviderCloseIsIdempotent(t *testing.T) {
+	p := NewAsyncProvider(benchNoopProvider{}, 1)
+	p.Close()
+	p.Close() // must not panic
+}
+
+// TestAsyncProviderEnqueueDuringClose reproduces the scenario a
+// concurrent Log during Close used to panic on ("send on closed
+// channel") before enqueue started checking the closed flag under the
+// same mutex Close holds while closing the queue.
+func TestAsyncProviderEnqueueDuringClose(t *testing.T) {
+	p := NewAsyncProvider(benchNoopProvider{}, 16)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			p.Log([]byte("msg"))
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		p.Close()
+	}()
+
+	wg.Wait()
+}
+
+func TestAsyncProviderLogCtxAbortsWhenDone(t *testing.T) {
+	inner := &blockingProvider{release: make(chan struct{})}
+	close(inner.release)
+
+	p := NewAsyncProvider(inner, 8)
+	defer p.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Give the worker a head start so it actually reaches the item
+	// (rather than racing the test's own assertions below).
+	p.LogCtx(ctx, []byte("should be aborted"))
+	time.Sleep(50 * time.Millisecond)
+
+	if got := p.Aborted(); got != 1 {
+		t.Errorf("expected 1 aborted message, got %d", got)
+	}
+
+	inner.mu.Lock()
+	defer inner.mu.Unlock()
+	if len(inner.got) != 0 {
+		t.Errorf("expected the cancelled message not to reach the inner provider, got %d delivered", len(inner.got))
+	}
+}
+
+// retryingCtxProvider is a ContextProvider whose LogCtx mimics
+// telegramSender/syslogSender's retry loop: it keeps "retrying" on a
+// fixed interval until either attempts run out or the per-message ctx
+// passed to LogCtx is done. It's used to confirm AsyncProvider actually
+// forwards an item's ctx into a ContextProvider inner, rather than only
+// checking it before the send starts.
+type retryingCtxProvider struct {
+	mu       sync.Mutex
+	attempts int
+	doneCtx  bool
+}
+
+func (p *retryingCtxProvider) GetID() string    { return "retrying" }
+func (p *retryingCtxProvider) Log(msg []byte)   {}
+func (p *retryingCtxProvider) Error(msg []byte) {}
+func (p *retryingCtxProvider) Fatal(msg []byte) {}
+func (p *retryingCtxProvider) Debug(msg []byte) {}
+func (p *retryingCtxProvider) LogCtx(ctx context.Context, msg []byte) {
+	for i := 0; i < 50; i++ {
+		p.mu.Lock()
+		p.attempts++
+		p.mu.Unlock()
+
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			p.mu.Lock()
+			p.doneCtx = true
+			p.mu.Unlock()
+			return
+		}
+	}
+}
+func (p *retryingCtxProvider) ErrorCtx(ctx context.Context, msg []byte) {}
+func (p *retryingCtxProvider) FatalCtx(ctx context.Context, msg []byte) {}
+func (p *retryingCtxProvider) DebugCtx(ctx context.Context, msg []byte) {}
+
+func TestAsyncProviderForwardsCtxIntoInFlightRetry(t *testing.T) {
+	inner := &retryingCtxProvider{}
+	p := NewAsyncProvider(inner, 8)
+	defer p.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.LogCtx(ctx, []byte("msg"))
+
+	// Let the retry loop get under way, then cancel mid-flight — the
+	// whole point being that this is not the already-cancelled case
+	// AsyncProvider's own pre-send check covers.
+	time.Sleep(35 * time.Millisecond)
+	cancel()
+	time.Sleep(35 * time.Millisecond)
+
+	inner.mu.Lock()
+	defer inner.mu.Unlock()
+	if !inner.doneCtx {
+		t.Error("expected the in-flight retry loop to observe ctx.Done() instead of running to completion")
+	}
+	if inner.attempts >= 50 {
+		t.Errorf("expected cancellation to cut the retry loop short, got %d attempts", inner.attempts)
+	}
+}
+
+func TestAsyncProviderLogCtxDeliversWhenNotDone(t *testing.T) {
+	inner := &blockingProvider{release: make(chan struct{})}
+	close(inner.release)
+
+	p := NewAsyncProvider(inner, 8)
+	defer p.Close()
+
+	p.LogCtx(context.Background(), []byte("should be delivered"))
+	time.Sleep(50 * time.Millisecond)
+
+	if got := p.Aborted(); got != 0 {
+		t.Errorf("expected 0 aborted messages, got %d", got)
+	}
+
+	inner.mu.Lock()
+	defer inner.mu.Unlock()
+	if len(inner.got) != 1 {
+		t.Errorf("expected the message to be delivered, got %d", len(inner.got))
+	}
+}
@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// defaultMaxTraceDepth bounds how many frames TRACE_TYPE_ANY records
+// when SetMaxTraceDepth hasn't overridden it.
+const defaultMaxTraceDepth = 32
+
+// CallerResolver turns the frames captureFrames collects into the
+// string embedded in a legacy message or a Record's Caller field.
+// Install a custom one with SetCallerResolver — for instance to strip
+// a GOPATH/module prefix, or to emit "pkg/file.go:line" instead of
+// just the basename.
+type CallerResolver interface {
+	Resolve(frames []runtime.Frame) string
+}
+
+// defaultCallerResolver reproduces the package's historical
+// "file.go:123, file.go:456" format, one entry per frame.
+type defaultCallerResolver struct{}
+
+func (defaultCallerResolver) Resolve(frames []runtime.Frame) string {
+	if len(frames) == 0 {
+		return ""
+	}
+
+	parts := make([]string, len(frames))
+	for i, f := range frames {
+		parts[i] = fmt.Sprintf("%s:%d", filepath.Base(f.File), f.Line)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// packagePrefix is this package's own fully-qualified import path plus
+// a trailing dot, e.g. "github.com/IntelliQru/logger.". It is derived
+// from a live stack frame rather than hardcoded, and is compared
+// against frame.Function (not a formatted "file.go:line" string), so
+// an unrelated caller whose own source file happens to be named
+// logger.go is never mistaken for this package's plumbing.
+var packagePrefix = func() string {
+	pc, _, _, ok := runtime.Caller(0)
+	if !ok {
+		return ""
+	}
+
+	name := runtime.FuncForPC(pc).Name() // e.g. "github.com/IntelliQru/logger.glob..func1"
+	slash := strings.LastIndex(name, "/")
+	rest := name[slash+1:]
+
+	dot := strings.Index(rest, ".")
+	if dot == -1 {
+		return name + "."
+	}
+
+	return name[:len(name)-len(rest)+dot+1]
+}()
+
+// isInternalFrame reports whether frame belongs to this package's own
+// implementation rather than to a caller. Test files are deliberately
+// excluded even though they share the package, so a trace captured
+// from within this package's own tests still shows the test's call
+// site instead of skipping straight past it.
+func isInternalFrame(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, packagePrefix) && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+// captureFrames walks the stack of the caller of whatever called
+// captureFrames, skipping this package's own frames, and returns up to
+// maxDepth of what's left. It stops early on hitting the testing
+// package, so TRACE_TYPE_ANY doesn't run all the way up to
+// testing.tRunner and the runtime's own startup frames.
+func captureFrames(maxDepth int) []runtime.Frame {
+	if maxDepth <= 0 {
+		maxDepth = 1
+	}
+
+	// Padded so that frames filtered out as internal don't eat into
+	// the budget of frames we actually want to keep.
+	const pad = 16
+	pcs := make([]uintptr, maxDepth+pad)
+	n := runtime.Callers(2, pcs) // skip runtime.Callers and captureFrames itself
+	frames := runtime.CallersFrames(pcs[:n])
+
+	out := make([]runtime.Frame, 0, maxDepth)
+	for {
+		frame, more := frames.Next()
+
+		switch {
+		case isInternalFrame(frame):
+		case strings.HasPrefix(frame.Function, "testing."):
+			return out
+		default:
+			out = append(out, frame)
+		}
+
+		if !more || len(out) >= maxDepth {
+			return out
+		}
+	}
+}
+
+// SetMaxTraceDepth bounds how many stack frames are recorded under
+// TRACE_TYPE_ANY; n <= 0 restores the default of defaultMaxTraceDepth.
+// It has no effect under TRACE_TYPE_ONE, which always records exactly
+// one frame.
+func (l *Logger) SetMaxTraceDepth(n int) {
+	l.maxTraceDepth = n
+}
+
+func (l *Logger) traceDepth() int {
+	if l.traceType == TRACE_TYPE_ONE {
+		return 1
+	}
+	if l.maxTraceDepth > 0 {
+		return l.maxTraceDepth
+	}
+	return defaultMaxTraceDepth
+}
+
+// SetCallerResolver installs r as the Logger's CallerResolver. A nil
+// resolver (the default) reproduces the historical "file.go:123, ..."
+// format.
+func (l *Logger) SetCallerResolver(r CallerResolver) {
+	l.callerResolver = r
+}
+
+func (l *Logger) resolver() CallerResolver {
+	if l.callerResolver != nil {
+		return l.callerResolver
+	}
+	return defaultCallerResolver{}
+}
+
+// resolveCaller captures and formats the caller of whoever called
+// resolveCaller, honoring the Logger's trace type/depth and
+// CallerResolver.
+func (l *Logger) resolveCaller() string {
+	return l.resolver().Resolve(captureFrames(l.traceDepth()))
+}
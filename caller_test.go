@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestIsInternalFrameMatchesPackageFrames(t *testing.T) {
+	frame := runtime.Frame{Function: packagePrefix + "Logger.Log", File: "logger.go"}
+	if !isInternalFrame(frame) {
+		t.Error("expected a frame whose Function is in this package to be internal")
+	}
+}
+
+func TestIsInternalFrameExcludesTestFiles(t *testing.T) {
+	frame := runtime.Frame{Function: packagePrefix + "TestSomething", File: "caller_test.go"}
+	if isInternalFrame(frame) {
+		t.Error("expected a _test.go frame to never be treated as internal, even within this package")
+	}
+}
+
+// TestIsInternalFrameIgnoresUnrelatedFileNamedLogger is a regression
+// test for the bug this request fixed: the old stack walk matched on
+// the bare filename ("logger.go"), so an unrelated caller whose own
+// source file happened to be named logger.go (or testing.go) was
+// mistaken for this package's internal plumbing and skipped. Matching
+// on frame.Function's package prefix instead means only frames that
+// actually belong to this package are ever treated as internal.
+func TestIsInternalFrameIgnoresUnrelatedFileNamedLogger(t *testing.T) {
+	frame := runtime.Frame{Function: "github.com/someoneelse/otherpkg.DoWork", File: "logger.go"}
+	if isInternalFrame(frame) {
+		t.Error("expected a frame from an unrelated package to not be internal just because its file is named logger.go")
+	}
+
+	frame = runtime.Frame{Function: "github.com/someoneelse/otherpkg.DoWork", File: "testing.go"}
+	if isInternalFrame(frame) {
+		t.Error("expected a frame from an unrelated package to not be internal just because its file is named testing.go")
+	}
+}
+
+// prefixStrippingResolver is a custom CallerResolver used to verify
+// SetCallerResolver actually takes effect: it swaps the real caller
+// location for a fixed marker, rather than reproducing
+// defaultCallerResolver's "file.go:123" format.
+type prefixStrippingResolver struct{}
+
+func (prefixStrippingResolver) Resolve(frames []runtime.Frame) string {
+	return "CUSTOM_RESOLVER_MARKER"
+}
+
+func TestSetCallerResolverIsUsedByLog(t *testing.T) {
+	provider := &recordingProvider{id: "rec"}
+	l := NewLogger()
+	l.SetLevel(LEVEL_INFO)
+	l.RegisterProvider(provider)
+	l.AddLogProvider(provider.GetID())
+	l.SetCallerResolver(prefixStrippingResolver{})
+
+	l.Log("hello")
+
+	if len(provider.logged) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(provider.logged))
+	}
+	if !strings.Contains(string(provider.logged[0]), "CUSTOM_RESOLVER_MARKER") {
+		t.Errorf("expected the custom CallerResolver's output in the message, got %q", provider.logged[0])
+	}
+}
+
+func TestSetCallerResolverIsUsedByLogKV(t *testing.T) {
+	provider := &recordingProvider{id: "rec"}
+	l := NewLogger()
+	l.SetLevel(LEVEL_INFO)
+	l.RegisterProvider(provider)
+	l.AddLogProvider(provider.GetID())
+	l.SetCallerResolver(prefixStrippingResolver{})
+
+	l.Infow("hello", "k", "v")
+
+	if len(provider.logged) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(provider.logged))
+	}
+	if !strings.Contains(string(provider.logged[0]), "CUSTOM_RESOLVER_MARKER") {
+		t.Errorf("expected the custom CallerResolver's output in the message, got %q", provider.logged[0])
+	}
+}
+
+func TestResolverFallsBackToDefault(t *testing.T) {
+	l := NewLogger()
+	if _, ok := l.resolver().(defaultCallerResolver); !ok {
+		t.Error("expected a Logger with no CallerResolver installed to fall back to defaultCallerResolver")
+	}
+
+	l.SetCallerResolver(prefixStrippingResolver{})
+	if _, ok := l.resolver().(prefixStrippingResolver); !ok {
+		t.Error("expected resolver() to return the installed CallerResolver")
+	}
+}
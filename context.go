@@ -0,0 +1,51 @@
+package logger
+
+import "context"
+
+// contextKey is an unexported type so values this package stores in a
+// context.Context can't collide with keys set by other packages.
+type contextKey struct{}
+
+var loggerContextKey = contextKey{}
+
+// NewContext returns a copy of ctx carrying l, retrievable later with
+// FromContext. Typical use is HTTP middleware that attaches a
+// request-scoped logger (see WithFields) once per request.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// FromContext returns the Logger stored in ctx by NewContext, or a
+// fresh, providerless Logger if none was attached. The fallback never
+// logs anywhere, so code can call FromContext unconditionally without
+// a nil check.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerContextKey).(*Logger); ok {
+		return l
+	}
+
+	return NewLogger()
+}
+
+// WithFields returns a child logger that shares this Logger's providers
+// and settings but adds the given key/value pairs (e.g. request_id,
+// trace_id, user) to every record it logs afterwards. It is an alias
+// for With, named to match the kv-pair vocabulary of LogKV/Infow/
+// Errorw/Debugw.
+func (l *Logger) WithFields(kv ...interface{}) *Logger {
+	return l.With(kv...)
+}
+
+// WithContext returns a child logger that carries ctx alongside this
+// Logger's providers, settings and fields. A provider that implements
+// ContextProvider (AsyncProvider, and anything built on top of it, such
+// as the Telegram and syslog providers) uses ctx to drop a message that
+// is still queued, or not yet started, once ctx is done — so a
+// cancelled request can abort its own pending log delivery instead of
+// outliving the request that produced it. Providers that don't
+// implement ContextProvider ignore ctx entirely.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	child := *l
+	child.ctx = ctx
+	return &child
+}
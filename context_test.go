@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewContextFromContextRoundTrip(t *testing.T) {
+	l := NewLogger()
+	ctx := NewContext(context.Background(), l)
+
+	got := FromContext(ctx)
+	if got != l {
+		t.Errorf("FromContext did not return the Logger stored by NewContext")
+	}
+}
+
+func TestFromContextFallback(t *testing.T) {
+	got := FromContext(context.Background())
+	if got == nil {
+		t.Fatal("expected a non-nil fallback Logger")
+	}
+
+	// The fallback Logger has no providers registered, so logging
+	// through it must be a no-op rather than panicking.
+	got.Log("should go nowhere")
+}
+
+func TestWithFieldsIsAliasForWith(t *testing.T) {
+	l := NewLogger()
+	child := l.WithFields("request_id", "abc123")
+
+	if len(child.fields) != 1 || child.fields[0].Key != "request_id" || child.fields[0].Value != "abc123" {
+		t.Errorf("expected WithFields to attach the given fields, got %+v", child.fields)
+	}
+}
+
+func TestWithFieldsPropagatesToLegacyPath(t *testing.T) {
+	provider := &recordingProvider{id: "rec"}
+	l := NewLogger()
+	l.SetLevel(LEVEL_INFO)
+	l.RegisterProvider(provider)
+	l.AddErrorProvider(provider.GetID())
+
+	child := l.WithFields("request_id", "abc123")
+	child.Errorf("boom")
+
+	if len(provider.errored) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(provider.errored))
+	}
+	if !strings.Contains(string(provider.errored[0]), "request_id=abc123") {
+		t.Errorf("expected request_id field in legacy Errorf output, got %q", provider.errored[0])
+	}
+}
+
+func TestWithFieldsPropagatesToStructuredPath(t *testing.T) {
+	provider := &recordingProvider{id: "rec"}
+	l := NewLogger()
+	l.SetLevel(LEVEL_INFO)
+	l.RegisterProvider(provider)
+	l.AddErrorProvider(provider.GetID())
+
+	child := l.WithFields("request_id", "abc123")
+	child.Errorw("boom", "extra", "field")
+
+	if len(provider.errored) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(provider.errored))
+	}
+	msg := string(provider.errored[0])
+	if !strings.Contains(msg, "request_id=abc123") {
+		t.Errorf("expected request_id field in structured Errorw output, got %q", msg)
+	}
+	if !strings.Contains(msg, "extra=field") {
+		t.Errorf("expected call-site field in structured Errorw output, got %q", msg)
+	}
+}
+
+func TestWithFieldsSharesSuppressedCounter(t *testing.T) {
+	provider := &recordingProvider{id: "rec"}
+	l := NewLogger()
+	l.SetLevel(LEVEL_INFO)
+	l.RegisterProvider(provider)
+	l.AddLogProvider(provider.GetID())
+	l.SetSampler(NewTieredSampler(1, 0, time.Minute))
+
+	child := l.WithFields("request_id", "abc123")
+
+	// Both calls must come from the same source line so the
+	// TieredSampler's call-site key matches between them.
+	for i := 0; i < 2; i++ {
+		child.Log("repeated message")
+	}
+
+	if got := l.Stats().Suppressed; got != 1 {
+		t.Errorf("expected the parent's Stats().Suppressed to see suppression through a WithFields child, got %d", got)
+	}
+	if got := child.Stats().Suppressed; got != 1 {
+		t.Errorf("expected child.Stats().Suppressed == 1, got %d", got)
+	}
+}
+
+func TestWithChainsFields(t *testing.T) {
+	l := NewLogger()
+	child := l.With("a", 1).With("b", 2)
+
+	if len(child.fields) != 2 {
+		t.Fatalf("expected fields from both With calls to accumulate, got %+v", child.fields)
+	}
+}
+
+// ctxProviderSpy is a ContextProvider that records whether its plain
+// (Log/Error/...) or *Ctx methods were invoked, so tests can verify
+// Logger.WithContext actually changes which one a dispatch uses.
+type ctxProviderSpy struct {
+	plainCalled bool
+	ctxCalled   bool
+	ctxSeen     context.Context
+}
+
+func (p *ctxProviderSpy) GetID() string    { return "ctxspy" }
+func (p *ctxProviderSpy) Log(msg []byte)   { p.plainCalled = true }
+func (p *ctxProviderSpy) Error(msg []byte) { p.plainCalled = true }
+func (p *ctxProviderSpy) Fatal(msg []byte) { p.plainCalled = true }
+func (p *ctxProviderSpy) Debug(msg []byte) { p.plainCalled = true }
+func (p *ctxProviderSpy) LogCtx(ctx context.Context, msg []byte) {
+	p.ctxCalled, p.ctxSeen = true, ctx
+}
+func (p *ctxProviderSpy) ErrorCtx(ctx context.Context, msg []byte) {
+	p.ctxCalled, p.ctxSeen = true, ctx
+}
+func (p *ctxProviderSpy) FatalCtx(ctx context.Context, msg []byte) {
+	p.ctxCalled, p.ctxSeen = true, ctx
+}
+func (p *ctxProviderSpy) DebugCtx(ctx context.Context, msg []byte) {
+	p.ctxCalled, p.ctxSeen = true, ctx
+}
+
+func TestWithContextRoutesThroughContextProvider(t *testing.T) {
+	provider := &ctxProviderSpy{}
+	l := NewLogger()
+	l.SetLevel(LEVEL_INFO)
+	l.RegisterProvider(provider)
+	l.AddLogProvider(provider.GetID())
+
+	ctx := context.WithValue(context.Background(), contextKey{}, nil)
+	child := l.WithContext(ctx)
+	child.Log("hello")
+
+	if !provider.ctxCalled || provider.plainCalled {
+		t.Errorf("expected WithContext to route through LogCtx, got plainCalled=%v ctxCalled=%v",
+			provider.plainCalled, provider.ctxCalled)
+	}
+	if provider.ctxSeen != ctx {
+		t.Error("expected the provider to receive the exact context passed to WithContext")
+	}
+}
+
+func TestWithoutContextUsesPlainMethods(t *testing.T) {
+	provider := &ctxProviderSpy{}
+	l := NewLogger()
+	l.SetLevel(LEVEL_INFO)
+	l.RegisterProvider(provider)
+	l.AddLogProvider(provider.GetID())
+
+	l.Log("hello")
+
+	if !provider.plainCalled || provider.ctxCalled {
+		t.Errorf("expected a Logger without WithContext to use Log, got plainCalled=%v ctxCalled=%v",
+			provider.plainCalled, provider.ctxCalled)
+	}
+}
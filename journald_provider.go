@@ -0,0 +1,103 @@
+//go:build linux
+// +build linux
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"regexp"
+	"strconv"
+)
+
+const PROVIDER_JOURNALD = "journald"
+
+const journaldSocketPath = "/run/systemd/journal/socket"
+
+// journaldCallerRe pulls a "file.go:123" style location out of the
+// already-formatted message text so it can be sent as CODE_FILE/
+// CODE_LINE and indexed by journald.
+var journaldCallerRe = regexp.MustCompile(`([\w./-]+\.go):(\d+)`)
+
+// JournaldProvider writes key=value fields straight to the native
+// sd_journal socket, so entries show up with proper PRIORITY/MESSAGE/
+// CODE_FILE/CODE_LINE fields instead of being swallowed as raw text.
+type JournaldProvider struct {
+	levelFilter
+	conn       *net.UnixConn
+	identifier string
+}
+
+// NewJournaldProvider connects to the local journald socket. identifier
+// is sent as SYSLOG_IDENTIFIER.
+func NewJournaldProvider(identifier string) (*JournaldProvider, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocketPath, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &JournaldProvider{levelFilter: newLevelFilter(), conn: conn, identifier: identifier}, nil
+}
+
+func (p *JournaldProvider) GetID() string {
+	return PROVIDER_JOURNALD
+}
+
+func (p *JournaldProvider) Log(msg []byte) {
+	if p.allow(LEVEL_INFO) {
+		p.send(6, msg) // LOG_INFO
+	}
+}
+
+func (p *JournaldProvider) Error(msg []byte) {
+	if p.allow(LEVEL_ERROR) {
+		p.send(3, msg) // LOG_ERR
+	}
+}
+
+func (p *JournaldProvider) Fatal(msg []byte) {
+	if p.allow(LEVEL_ERROR) {
+		p.send(2, msg) // LOG_CRIT
+	}
+}
+
+func (p *JournaldProvider) Debug(msg []byte) {
+	if p.allow(LEVEL_DEBUG) {
+		p.send(7, msg) // LOG_DEBUG
+	}
+}
+
+func (p *JournaldProvider) send(priority int, msg []byte) {
+	buf := bytes.NewBuffer(nil)
+
+	writeJournaldField(buf, "PRIORITY", []byte(strconv.Itoa(priority)))
+	writeJournaldField(buf, "SYSLOG_IDENTIFIER", []byte(p.identifier))
+	writeJournaldField(buf, "MESSAGE", msg)
+
+	if m := journaldCallerRe.FindSubmatch(msg); m != nil {
+		writeJournaldField(buf, "CODE_FILE", m[1])
+		writeJournaldField(buf, "CODE_LINE", m[2])
+	}
+
+	p.conn.Write(buf.Bytes())
+}
+
+// writeJournaldField appends one field in the native journal protocol:
+// "KEY=value\n" for single-line values, or "KEY\n" + little-endian
+// uint64 length + value + "\n" for values containing a newline.
+func writeJournaldField(buf *bytes.Buffer, key string, value []byte) {
+	if !bytes.ContainsRune(value, '\n') {
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.Write(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteString(key)
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.Write(value)
+	buf.WriteByte('\n')
+}
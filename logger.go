@@ -2,11 +2,12 @@ package logger
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -27,6 +28,28 @@ type ProviderInterface interface {
 	Debug(msg []byte)
 }
 
+// StructuredProvider is implemented by providers that want the full
+// Record (timestamp, level, message, caller, host and fields) instead
+// of a pre-formatted line. LogKV/Infow/Errorw/Debugw dispatch to this
+// interface first and only fall back to ProviderInterface's []byte
+// methods when it isn't implemented. Log/Error/Debug/Fatal (and their f
+// variants) always deliver the pre-formatted []byte line, even to a
+// StructuredProvider.
+type StructuredProvider interface {
+	ProviderInterface
+	LogRecord(rec Record)
+}
+
+// Format selects how LogKV/Infow/Errorw/Debugw records are serialized
+// for providers that only implement ProviderInterface.
+type Format int
+
+const (
+	FormatText Format = iota
+	FormatJSON
+	FormatLogfmt
+)
+
 type Logger struct {
 	providers      map[string]*ProviderInterface
 	logProviders   []string
@@ -35,14 +58,32 @@ type Logger struct {
 	debugProviders []string
 	level          int
 	traceType      int
+	format         Format
+	fields         []Field
+	ctx            context.Context
+	sampler        Sampler
+	suppressed     *uint64
+	maxTraceDepth  int
+	callerResolver CallerResolver
 }
 
 func NewLogger() *Logger {
 	return &Logger{
-		providers: make(map[string]*ProviderInterface, 0),
+		providers:  make(map[string]*ProviderInterface, 0),
+		suppressed: new(uint64),
 	}
 }
 
+// SetFormat selects the wire format used when a message has to be
+// serialized to []byte for a provider that doesn't implement
+// StructuredProvider. It applies to LogKV/Infow/Errorw/Debugw only:
+// Log/Error/Debug/Fatal (and their f variants) always produce the text
+// format. It has no effect on providers that implement
+// StructuredProvider.
+func (l *Logger) SetFormat(format Format) {
+	l.format = format
+}
+
 func (l *Logger) SetTraceType(val int) error {
 
 	if val != TRACE_TYPE_ONE && val != TRACE_TYPE_ANY {
@@ -126,11 +167,14 @@ func (l *Logger) Log(messageParts ...interface{}) {
 	if l.level < LEVEL_INFO {
 		return
 	}
-	msg := makeMessage("LOG", messageParts, l.traceType)
+	msg := makeMessage("LOG", messageParts, l.traceDepth(), l.resolver(), l.fields)
+	if l.suppress(LEVEL_INFO, msg) {
+		return
+	}
 	for _, pID := range l.logProviders {
 		p, bFound := l.providers[pID]
 		if bFound {
-			(*p).Log(msg)
+			l.deliverOne(*p, "LOG", msg)
 		}
 	}
 }
@@ -141,11 +185,14 @@ func (l *Logger) Errorf(format string, params ...interface{}) {
 
 func (l *Logger) Error(messageParts ...interface{}) {
 
-	msg := makeMessage("ERROR", messageParts, l.traceType)
+	msg := makeMessage("ERROR", messageParts, l.traceDepth(), l.resolver(), l.fields)
+	if l.suppress(LEVEL_ERROR, msg) {
+		return
+	}
 	for _, pID := range l.errorProviders {
 		p, bFound := l.providers[pID]
 		if bFound {
-			(*p).Error(msg)
+			l.deliverOne(*p, "ERROR", msg)
 		}
 	}
 }
@@ -163,11 +210,14 @@ func (l *Logger) Debug(messageParts ...interface{}) {
 		return
 	}
 
-	msg := makeMessage("DEBUG", messageParts, l.traceType)
+	msg := makeMessage("DEBUG", messageParts, l.traceDepth(), l.resolver(), l.fields)
+	if l.suppress(LEVEL_DEBUG, msg) {
+		return
+	}
 	for _, pID := range l.debugProviders {
 		p, bFound := l.providers[pID]
 		if bFound {
-			(*p).Debug(msg)
+			l.deliverOne(*p, "DEBUG", msg)
 		}
 	}
 }
@@ -177,56 +227,225 @@ func (l *Logger) Fatalf(format string, params ...interface{}) {
 }
 
 func (l *Logger) Fatal(messageParts ...interface{}) {
-	msg := makeMessage("FATAL", messageParts, l.traceType)
+	msg := makeMessage("FATAL", messageParts, l.traceDepth(), l.resolver(), l.fields)
 	for _, pID := range l.fatalProviders {
 		p, bFound := l.providers[pID]
 		if bFound {
-			(*p).Fatal(msg)
+			l.deliverOne(*p, "FATAL", msg)
 		}
 	}
 
 	os.Exit(1)
 }
 
-var (
-	HOST                 string
-	MESSAGE_REPLACER     = strings.NewReplacer("\r", "", "\n", "\t")
-	MESSAGE_SEPARATOR    = []byte(" ")
-	LOGGER_LINE_REPLACER = strings.NewReplacer(": ", "", " ", "", "\n", ", ")
-)
+// deliverOne sends msg to p via the method typeLog names (LOG, ERROR,
+// DEBUG or FATAL). If this Logger carries a context (see WithContext)
+// and p implements ContextProvider, delivery goes through the *Ctx
+// variant instead, so a provider backed by a queue (AsyncProvider, and
+// anything built on it) can drop msg once that context is done rather
+// than deliver it regardless.
+func (l *Logger) deliverOne(p ProviderInterface, typeLog string, msg []byte) {
+	if cp, ok := p.(ContextProvider); ok && l.ctx != nil {
+		switch typeLog {
+		case "ERROR":
+			cp.ErrorCtx(l.ctx, msg)
+		case "DEBUG":
+			cp.DebugCtx(l.ctx, msg)
+		case "FATAL":
+			cp.FatalCtx(l.ctx, msg)
+		default:
+			cp.LogCtx(l.ctx, msg)
+		}
+		return
+	}
 
-func makeMessage(typeLog string, err []interface{}, traceType int) []byte {
+	switch typeLog {
+	case "ERROR":
+		p.Error(msg)
+	case "DEBUG":
+		p.Debug(msg)
+	case "FATAL":
+		p.Fatal(msg)
+	default:
+		p.Log(msg)
+	}
+}
 
-	if len(HOST) == 0 {
-		HOST, _ = os.Hostname()
+// Stats reports per-provider counters, currently the number of messages
+// dropped by providers that apply backpressure (AsyncProvider and
+// anything wrapping it), the number aborted because their request's
+// context was already done (see WithContext), plus the number of
+// messages the Sampler dropped before they ever reached a provider.
+type Stats struct {
+	Dropped    map[string]uint64
+	Aborted    map[string]uint64
+	Suppressed uint64
+}
+
+func (l *Logger) Stats() Stats {
+	stats := Stats{
+		Dropped:    make(map[string]uint64),
+		Aborted:    make(map[string]uint64),
+		Suppressed: atomic.LoadUint64(l.suppressed),
 	}
 
-	buf := bytes.NewBuffer(nil)
-	lineBuf := bytes.NewBuffer(nil)
-	logger := log.New(buf, "", log.Lshortfile)
+	for id, p := range l.providers {
+		if d, ok := (*p).(interface{ Dropped() uint64 }); ok {
+			stats.Dropped[id] = d.Dropped()
+		}
+		if a, ok := (*p).(interface{ Aborted() uint64 }); ok {
+			stats.Aborted[id] = a.Aborted()
+		}
+	}
 
-	for i := 2; i < 6; i++ {
-		logger.Output(i, "")
-		val := buf.String() // example: <filename>:<line number>:\n => testing.go:107\n
-		buf.Reset()
+	return stats
+}
 
-		if strings.HasPrefix(val, "logger.go:") {
-			continue // skip current module
-		} else if strings.HasPrefix(val, "testing.go:") {
-			break
+// Close shuts down every registered provider that needs it (currently
+// AsyncProvider and anything built on top of it), draining their queues
+// before returning.
+func (l *Logger) Close() {
+	for _, p := range l.providers {
+		if c, ok := (*p).(interface{ Close() }); ok {
+			c.Close()
 		}
+	}
+}
 
-		lineBuf.WriteString(val)
+// With returns a child logger that shares this Logger's providers and
+// settings but adds the given key/value pairs to every message it logs
+// afterwards — Log/Error/Debug/Fatal (and their f variants) as well as
+// LogKV/Infow/Errorw/Debugw. They follow the message text, ahead of any
+// key/value pairs passed at the call site. kv is read as alternating key,
+// value, ... pairs, the same convention LogKV/Infow/Errorw/Debugw use.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	child := *l
+	child.fields = append(append([]Field{}, l.fields...), kvToFields(kv...)...)
+	return &child
+}
 
-		if traceType == TRACE_TYPE_ONE {
-			break
+// LogKV logs msg together with an explicit set of key/value fields,
+// routing to the same provider lists as Log/Error/Debug depending on
+// level (LEVEL_ERROR, LEVEL_INFO or LEVEL_DEBUG).
+func (l *Logger) LogKV(level int, msg string, kv ...interface{}) {
+	switch level {
+	case LEVEL_ERROR:
+		l.dispatch("ERROR", l.errorProviders, msg, kv)
+	case LEVEL_DEBUG:
+		if l.level < LEVEL_DEBUG {
+			return
 		}
+		l.dispatch("DEBUG", l.debugProviders, msg, kv)
+	default:
+		if l.level < LEVEL_INFO {
+			return
+		}
+		l.dispatch("LOG", l.logProviders, msg, kv)
 	}
+}
 
-	line := strings.TrimRight(LOGGER_LINE_REPLACER.Replace(lineBuf.String()), ", ")
+// Infow logs msg at LEVEL_INFO with the given key/value fields.
+func (l *Logger) Infow(msg string, kv ...interface{}) {
+	l.LogKV(LEVEL_INFO, msg, kv...)
+}
+
+// Errorw logs msg at LEVEL_ERROR with the given key/value fields.
+func (l *Logger) Errorw(msg string, kv ...interface{}) {
+	l.LogKV(LEVEL_ERROR, msg, kv...)
+}
+
+// Debugw logs msg at LEVEL_DEBUG with the given key/value fields.
+func (l *Logger) Debugw(msg string, kv ...interface{}) {
+	l.LogKV(LEVEL_DEBUG, msg, kv...)
+}
+
+// suppress reports whether the sampler wants this message dropped,
+// counting it toward Stats().Suppressed when it does. There is no
+// sampler by default, so nothing is suppressed unless SetSampler was
+// called.
+func (l *Logger) suppress(level int, msg []byte) bool {
+	if l.sampler == nil || l.sampler.Allow(level, msg) {
+		return false
+	}
+
+	atomic.AddUint64(l.suppressed, 1)
+	return true
+}
+
+func (l *Logger) dispatch(typeLog string, providerIDs []string, msg string, kv []interface{}) {
+	rec := l.buildRecord(typeLog, msg, kv)
+	legacyMsg := encodeRecord(rec, l.format)
+
+	if l.suppress(recordLevel(typeLog), legacyMsg) {
+		return
+	}
+
+	for _, pID := range providerIDs {
+		p, bFound := l.providers[pID]
+		if !bFound {
+			continue
+		}
+
+		if sp, ok := (*p).(StructuredProvider); ok {
+			sp.LogRecord(rec)
+			continue
+		}
+
+		l.deliverOne(*p, typeLog, legacyMsg)
+	}
+}
+
+func recordLevel(typeLog string) int {
+	switch typeLog {
+	case "ERROR":
+		return LEVEL_ERROR
+	case "DEBUG":
+		return LEVEL_DEBUG
+	default:
+		return LEVEL_INFO
+	}
+}
+
+func (l *Logger) buildRecord(typeLog string, msg string, kv []interface{}) Record {
+	if len(HOST) == 0 {
+		HOST, _ = os.Hostname()
+	}
+
+	return Record{
+		Timestamp: time.Now(),
+		Level:     typeLog,
+		Message:   msg,
+		Caller:    l.resolveCaller(),
+		Host:      HOST,
+		Fields:    append(append([]Field{}, l.fields...), kvToFields(kv...)...),
+	}
+}
+
+var (
+	HOST              string
+	MESSAGE_REPLACER  = strings.NewReplacer("\r", "", "\n", "\t")
+	MESSAGE_SEPARATOR = []byte(" ")
+)
+
+// makeMessage formats a legacy (non-structured) log line:
+//
+//	TYPE: RFC3339-timestamp host file.go:123, file.go:456: message key=value
+//
+// maxDepth and resolver come from the calling Logger's traceDepth() and
+// resolver(), so TRACE_TYPE_ONE/TRACE_TYPE_ANY and a custom
+// CallerResolver both apply here exactly as they do to structured
+// records built by buildRecord. fields is the Logger's own l.fields
+// (set via With/WithFields), appended the same way encodeRecordText
+// appends Record.Fields, so a child logger's fields show up on
+// Log/Error/Debug/Fatal just as they do on LogKV/Infow/Errorw/Debugw.
+func makeMessage(typeLog string, err []interface{}, maxDepth int, resolver CallerResolver, fields []Field) []byte {
+
+	if len(HOST) == 0 {
+		HOST, _ = os.Hostname()
+	}
+
+	line := resolver.Resolve(captureFrames(maxDepth))
 	prefix := fmt.Sprintf("%s: %s %s %s: ", typeLog, time.Now().Format(time.RFC3339), HOST, line)
-	logger.SetFlags(0)
-	logger.SetPrefix(prefix)
 
 	msg := bytes.NewBuffer(nil)
 	for i, v := range err {
@@ -236,9 +455,11 @@ func makeMessage(typeLog string, err []interface{}, traceType int) []byte {
 		fmt.Fprint(msg, v)
 	}
 
+	for _, f := range fields {
+		fmt.Fprintf(msg, " %s=%v", f.Key, f.Value)
+	}
+
 	// Example:
 	//  ERROR: 2016-11-21T14:50:23+03:00 khramtsov logger.go:124, logger_test.go:102: message text
-	logger.Output(0, MESSAGE_REPLACER.Replace(msg.String()))
-
-	return bytes.Replace(buf.Bytes(), []byte("\n"), []byte{}, -1)
+	return []byte(prefix + MESSAGE_REPLACER.Replace(msg.String()))
 }
@@ -54,7 +54,7 @@ func TestMessage(t *testing.T) {
 	testData := []interface{}{"line1\nline2", "line3\r\nline4", "text1", "text2", 10}
 
 	for _, prefix := range []string{"Err", "Info"} {
-		msg := string(makeMessage(prefix, testData, TRACE_TYPE_ANY))
+		msg := string(makeMessage(prefix, testData, defaultMaxTraceDepth, defaultCallerResolver{}, nil))
 		result := expr.ReplaceAllString(msg, "")
 
 		etalon := prefix + " line1\tline2 line3\tline4 text1 text2 10"
@@ -74,6 +74,7 @@ func TestFormat(t *testing.T) {
 	l.AddErrorProvider(provider.GetID())
 	l.AddFatalProvider(provider.GetID())
 	l.AddDebugProvider(provider.GetID())
+	l.SetMaxTraceDepth(2) // pin TRACE_TYPE_ANY to the 2 frames the assertions below expect
 
 	for _, traceType := range []int{TRACE_TYPE_ONE, TRACE_TYPE_ANY} {
 		l.SetTraceType(traceType)
@@ -103,13 +104,13 @@ func TestFormat(t *testing.T) {
 
 					switch traceType {
 					case TRACE_TYPE_ONE:
-						etalon := fmt.Sprintf("%s logger_test.go:124: format: text 10", strings.ToUpper(messageType))
+						etalon := fmt.Sprintf("%s logger_test.go:125: format: text 10", strings.ToUpper(messageType))
 
 						if msg != etalon {
 							t.Errorf("Failed format: \n'%v' !=\n'%v'(source: %s)", msg, etalon, str)
 						}
 					case TRACE_TYPE_ANY:
-						etalon := fmt.Sprintf("%s logger_test.go:124, logger_test.go:138: format: text 10", strings.ToUpper(messageType))
+						etalon := fmt.Sprintf("%s logger_test.go:125, logger_test.go:139: format: text 10", strings.ToUpper(messageType))
 
 						if msg != etalon {
 							t.Errorf("Failed format: \n'%v' !=\n'%v'(source: %s)", msg, etalon, str)
@@ -203,3 +204,38 @@ func (p *Provider) Error(msg []byte) {
 func (p *Provider) Fatal(msg []byte) {
 	panic("call Fatal: " + string(msg))
 }
+
+type benchNoopProvider struct{}
+
+func (benchNoopProvider) GetID() string    { return "bench" }
+func (benchNoopProvider) Log(msg []byte)   {}
+func (benchNoopProvider) Error(msg []byte) {}
+func (benchNoopProvider) Fatal(msg []byte) {}
+func (benchNoopProvider) Debug(msg []byte) {}
+
+func BenchmarkCaptureFrames(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		captureFrames(defaultMaxTraceDepth)
+	}
+}
+
+func BenchmarkMakeMessage(b *testing.B) {
+	data := []interface{}{"text1", "text2", 10}
+
+	for i := 0; i < b.N; i++ {
+		makeMessage("LOG", data, defaultMaxTraceDepth, defaultCallerResolver{}, nil)
+	}
+}
+
+func BenchmarkLoggerLog(b *testing.B) {
+	provider := benchNoopProvider{}
+	l := NewLogger()
+	l.SetLevel(LEVEL_INFO)
+	l.RegisterProvider(provider)
+	l.AddLogProvider(provider.GetID())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Log("text1", "text2", 10)
+	}
+}
@@ -0,0 +1,24 @@
+package logger
+
+// levelFilter is embedded by providers that want a SetMinLevel knob, so
+// a single Logger can route every level to one provider (say, a file)
+// while only forwarding ERROR and above to another (say, syslog).
+type levelFilter struct {
+	minLevel int
+}
+
+// newLevelFilter returns a filter that accepts every level until
+// SetMinLevel narrows it.
+func newLevelFilter() levelFilter {
+	return levelFilter{minLevel: LEVEL_DEBUG}
+}
+
+// SetMinLevel restricts the provider to levels at or more severe than
+// level (LEVEL_ERROR is most severe, LEVEL_DEBUG least).
+func (f *levelFilter) SetMinLevel(level int) {
+	f.minLevel = level
+}
+
+func (f *levelFilter) allow(level int) bool {
+	return level <= f.minLevel
+}
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Field is a single structured key/value pair attached to a Record.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Record is the structured form of a log line. Providers implementing
+// StructuredProvider receive it directly; everything else gets it
+// serialized via encodeRecord according to the Logger's Format.
+type Record struct {
+	Timestamp time.Time
+	Level     string
+	Message   string
+	Caller    string
+	Host      string
+	Fields    []Field
+}
+
+// kvToFields turns an alternating key, value, ... list into Fields. An
+// odd trailing value is kept under the "!BADKEY" key, mirroring the
+// convention log/slog uses for malformed argument lists.
+func kvToFields(kv ...interface{}) []Field {
+	fields := make([]Field, 0, len(kv)/2+len(kv)%2)
+
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 >= len(kv) {
+			fields = append(fields, Field{Key: "!BADKEY", Value: kv[i]})
+			break
+		}
+
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		fields = append(fields, Field{Key: key, Value: kv[i+1]})
+	}
+
+	return fields
+}
+
+func encodeRecord(rec Record, format Format) []byte {
+	switch format {
+	case FormatJSON:
+		return encodeRecordJSON(rec)
+	case FormatLogfmt:
+		return encodeRecordLogfmt(rec)
+	default:
+		return encodeRecordText(rec)
+	}
+}
+
+func encodeRecordText(rec Record) []byte {
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "%s: %s %s %s: %s", rec.Level, rec.Timestamp.Format(time.RFC3339), rec.Host, rec.Caller, rec.Message)
+
+	for _, f := range rec.Fields {
+		fmt.Fprintf(buf, " %s=%v", f.Key, f.Value)
+	}
+
+	return buf.Bytes()
+}
+
+func encodeRecordJSON(rec Record) []byte {
+	out := make(map[string]interface{}, len(rec.Fields)+5)
+	out["timestamp"] = rec.Timestamp.Format(time.RFC3339)
+	out["level"] = rec.Level
+	out["message"] = rec.Message
+	out["caller"] = rec.Caller
+	out["host"] = rec.Host
+
+	for _, f := range rec.Fields {
+		out[f.Key] = f.Value
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return []byte(fmt.Sprintf(`{"level":%q,"message":%q,"encode_error":%q}`, rec.Level, rec.Message, err.Error()))
+	}
+
+	return data
+}
+
+func encodeRecordLogfmt(rec Record) []byte {
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "ts=%s level=%s host=%s caller=%s msg=%s",
+		rec.Timestamp.Format(time.RFC3339), rec.Level, rec.Host, rec.Caller, logfmtQuote(rec.Message))
+
+	for _, f := range rec.Fields {
+		fmt.Fprintf(buf, " %s=%s", f.Key, logfmtQuote(fmt.Sprint(f.Value)))
+	}
+
+	return buf.Bytes()
+}
+
+func logfmtQuote(val string) string {
+	if strings.ContainsAny(val, " \t\"=") {
+		return fmt.Sprintf("%q", val)
+	}
+	return val
+}
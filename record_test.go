@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKVToFields(t *testing.T) {
+	fields := kvToFields("request_id", "abc123", "count", 10)
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 fields, got %d: %+v", len(fields), fields)
+	}
+	if fields[0].Key != "request_id" || fields[0].Value != "abc123" {
+		t.Errorf("unexpected field 0: %+v", fields[0])
+	}
+	if fields[1].Key != "count" || fields[1].Value != 10 {
+		t.Errorf("unexpected field 1: %+v", fields[1])
+	}
+}
+
+func TestKVToFieldsOddTrailing(t *testing.T) {
+	fields := kvToFields("a", 1, "orphan")
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 fields, got %d: %+v", len(fields), fields)
+	}
+	if fields[1].Key != "!BADKEY" || fields[1].Value != "orphan" {
+		t.Errorf("expected trailing value under !BADKEY, got %+v", fields[1])
+	}
+}
+
+func TestEncodeRecordText(t *testing.T) {
+	rec := Record{
+		Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:     "ERROR",
+		Message:   "boom",
+		Caller:    "record_test.go:1",
+		Host:      "myhost",
+		Fields:    []Field{{Key: "request_id", Value: "abc123"}},
+	}
+
+	got := string(encodeRecordText(rec))
+	want := "ERROR: 2026-01-02T03:04:05Z myhost record_test.go:1: boom request_id=abc123"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEncodeRecordJSON(t *testing.T) {
+	rec := Record{
+		Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:     "ERROR",
+		Message:   "boom",
+		Caller:    "record_test.go:1",
+		Host:      "myhost",
+		Fields:    []Field{{Key: "request_id", Value: "abc123"}},
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(encodeRecordJSON(rec), &out); err != nil {
+		t.Fatalf("encodeRecordJSON produced invalid JSON: %v", err)
+	}
+
+	if out["level"] != "ERROR" || out["message"] != "boom" || out["request_id"] != "abc123" {
+		t.Errorf("unexpected decoded record: %+v", out)
+	}
+}
+
+func TestEncodeRecordLogfmt(t *testing.T) {
+	rec := Record{
+		Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:     "ERROR",
+		Message:   "boom with spaces",
+		Caller:    "record_test.go:1",
+		Host:      "myhost",
+		Fields:    []Field{{Key: "request_id", Value: "abc123"}},
+	}
+
+	got := string(encodeRecordLogfmt(rec))
+	if !strings.Contains(got, `msg="boom with spaces"`) {
+		t.Errorf("expected quoted message in %q", got)
+	}
+	if !strings.Contains(got, "request_id=abc123") {
+		t.Errorf("expected request_id field in %q", got)
+	}
+}
+
+func TestLogKVRoutesByLevel(t *testing.T) {
+	provider := &recordingProvider{id: "rec"}
+	l := NewLogger()
+	l.SetLevel(LEVEL_DEBUG)
+	l.RegisterProvider(provider)
+	l.AddLogProvider(provider.GetID())
+	l.AddErrorProvider(provider.GetID())
+	l.AddDebugProvider(provider.GetID())
+
+	l.Infow("info message", "k", "v")
+	l.Errorw("error message", "k", "v")
+	l.Debugw("debug message", "k", "v")
+
+	if len(provider.logged) != 1 || len(provider.errored) != 1 || len(provider.debugged) != 1 {
+		t.Fatalf("expected one message per level, got logged=%d errored=%d debugged=%d",
+			len(provider.logged), len(provider.errored), len(provider.debugged))
+	}
+
+	if !strings.Contains(string(provider.errored[0]), "k=v") {
+		t.Errorf("expected fields in errored message, got %q", provider.errored[0])
+	}
+}
+
+// recordingProvider is a ProviderInterface that just keeps every
+// message it receives, so tests can assert on what was dispatched.
+type recordingProvider struct {
+	id       string
+	logged   [][]byte
+	errored  [][]byte
+	fataled  [][]byte
+	debugged [][]byte
+}
+
+func (p *recordingProvider) GetID() string { return p.id }
+func (p *recordingProvider) Log(msg []byte) {
+	p.logged = append(p.logged, msg)
+}
+func (p *recordingProvider) Error(msg []byte) {
+	p.errored = append(p.errored, msg)
+}
+func (p *recordingProvider) Fatal(msg []byte) {
+	p.fataled = append(p.fataled, msg)
+}
+func (p *recordingProvider) Debug(msg []byte) {
+	p.debugged = append(p.debugged, msg)
+}
@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const PROVIDER_ROTATING_FILE = "rotating_file"
+
+// RotatingFileProvider writes lines to a file, rotating it once it
+// crosses maxSize bytes or maxAge has elapsed since it was opened. Each
+// rotated segment is gzipped in the background; at most maxBackups of
+// them are kept, oldest first discarded.
+type RotatingFileProvider struct {
+	levelFilter
+
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// NewRotatingFileProvider opens (or creates) path for appending. A
+// maxSize/maxAge of 0 disables that rotation trigger; maxBackups <= 0
+// keeps every rotated segment.
+func NewRotatingFileProvider(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*RotatingFileProvider, error) {
+	p := &RotatingFileProvider{
+		levelFilter: newLevelFilter(),
+		path:        path,
+		maxSize:     maxSize,
+		maxAge:      maxAge,
+		maxBackups:  maxBackups,
+	}
+
+	if err := p.openCurrent(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *RotatingFileProvider) GetID() string {
+	return PROVIDER_ROTATING_FILE
+}
+
+func (p *RotatingFileProvider) Log(msg []byte) {
+	if p.allow(LEVEL_INFO) {
+		p.write(msg)
+	}
+}
+
+func (p *RotatingFileProvider) Error(msg []byte) {
+	if p.allow(LEVEL_ERROR) {
+		p.write(msg)
+	}
+}
+
+func (p *RotatingFileProvider) Fatal(msg []byte) {
+	if p.allow(LEVEL_ERROR) {
+		p.write(msg)
+	}
+}
+
+func (p *RotatingFileProvider) Debug(msg []byte) {
+	if p.allow(LEVEL_DEBUG) {
+		p.write(msg)
+	}
+}
+
+func (p *RotatingFileProvider) write(msg []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.needsRotation() {
+		if err := p.rotate(); err != nil {
+			return
+		}
+	}
+
+	line := append(append([]byte{}, msg...), '\n')
+	n, err := p.file.Write(line)
+	if err == nil {
+		p.size += int64(n)
+	}
+}
+
+func (p *RotatingFileProvider) needsRotation() bool {
+	if p.maxSize > 0 && p.size >= p.maxSize {
+		return true
+	}
+	if p.maxAge > 0 && time.Since(p.openedAt) >= p.maxAge {
+		return true
+	}
+	return false
+}
+
+func (p *RotatingFileProvider) openCurrent() error {
+	file, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	p.file = file
+	p.size = info.Size()
+	p.openedAt = time.Now()
+	return nil
+}
+
+func (p *RotatingFileProvider) rotate() error {
+	if err := p.file.Close(); err != nil {
+		return err
+	}
+
+	rotatedPath := fmt.Sprintf("%s.%s", p.path, time.Now().Format("20060102T150405"))
+	if err := os.Rename(p.path, rotatedPath); err != nil {
+		return err
+	}
+
+	if err := p.openCurrent(); err != nil {
+		return err
+	}
+
+	go p.compressAndPrune(rotatedPath)
+	return nil
+}
+
+func (p *RotatingFileProvider) compressAndPrune(rotatedPath string) {
+	if err := gzipFile(rotatedPath); err != nil {
+		return
+	}
+
+	p.pruneBackups()
+}
+
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		gw.Close()
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+func (p *RotatingFileProvider) pruneBackups() {
+	if p.maxBackups <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(p.path + ".*.gz")
+	if err != nil {
+		return
+	}
+	sort.Strings(matches)
+
+	if len(matches) <= p.maxBackups {
+		return
+	}
+
+	for _, old := range matches[:len(matches)-p.maxBackups] {
+		os.Remove(old)
+	}
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileProviderWritesLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	p, err := NewRotatingFileProvider(path, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("NewRotatingFileProvider: %v", err)
+	}
+	defer p.file.Close()
+
+	p.Log([]byte("first"))
+	p.Error([]byte("second"))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	want := "first\nsecond\n"
+	if string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
+
+func TestRotatingFileProviderLevelFilter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	p, err := NewRotatingFileProvider(path, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("NewRotatingFileProvider: %v", err)
+	}
+	defer p.file.Close()
+
+	p.SetMinLevel(LEVEL_ERROR)
+	p.Log([]byte("should be filtered out"))
+	p.Debug([]byte("should be filtered out"))
+	p.Error([]byte("should be written"))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	if string(data) != "should be written\n" {
+		t.Errorf("got %q, want only the error line", data)
+	}
+}
+
+func TestRotatingFileProviderRotatesOnSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	p, err := NewRotatingFileProvider(path, 10, 0, 0)
+	if err != nil {
+		t.Fatalf("NewRotatingFileProvider: %v", err)
+	}
+	defer p.file.Close()
+
+	p.Log([]byte("0123456789")) // exactly maxSize, triggers rotation before the next write
+	p.Log([]byte("next"))
+
+	// The rotated segment is gzipped in the background; give it a
+	// moment before checking the directory.
+	time.Sleep(100 * time.Millisecond)
+
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(matches) == 0 {
+		t.Errorf("expected at least one rotated segment in %s, found none", dir)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "next\n" {
+		t.Errorf("got %q, want only the post-rotation line", data)
+	}
+}
+
+// Syslog and journald providers dial a real local socket
+// (/dev/log, /run/systemd/journal/socket) and have no in-process fake
+// to substitute, so they are exercised manually rather than by a unit
+// test here.
@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"hash/fnv"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Sampler decides whether a message should reach providers at all. It
+// sits between Log/Error/Debug/LogKV and provider dispatch, so a flood
+// of identical errors can't melt a Telegram or syslog sink downstream.
+// Fatal is never sampled.
+type Sampler interface {
+	Allow(level int, msg []byte) bool
+}
+
+// SetSampler installs s as the Logger's Sampler. A nil sampler (the
+// default) allows everything through.
+func (l *Logger) SetSampler(s Sampler) {
+	l.sampler = s
+}
+
+// RateSampler is a token-bucket rate limiter: it allows perSecond
+// messages per second on average, with bursts up to burst.
+type RateSampler struct {
+	mu        sync.Mutex
+	tokens    float64
+	maxTokens float64
+	perSecond float64
+	last      time.Time
+}
+
+// NewRateSampler returns a RateSampler refilling at perSecond tokens a
+// second, holding at most burst of them (burst <= 0 defaults to
+// perSecond).
+func NewRateSampler(perSecond, burst int) *RateSampler {
+	if burst <= 0 {
+		burst = perSecond
+	}
+
+	return &RateSampler{
+		tokens:    float64(burst),
+		maxTokens: float64(burst),
+		perSecond: float64(perSecond),
+		last:      time.Now(),
+	}
+}
+
+func (s *RateSampler) Allow(level int, msg []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.tokens += now.Sub(s.last).Seconds() * s.perSecond
+	if s.tokens > s.maxTokens {
+		s.tokens = s.maxTokens
+	}
+	s.last = now
+
+	if s.tokens < 1 {
+		return false
+	}
+
+	s.tokens--
+	return true
+}
+
+// samplerCallerRe pulls the "file.go:123" call site out of an
+// already-formatted message so repeated messages from the same site
+// collapse to the same tiering key regardless of their exact text.
+var samplerCallerRe = regexp.MustCompile(`[\w./-]+\.go:\d+`)
+
+type tieredWindow struct {
+	count uint64
+	start time.Time
+}
+
+// TieredSampler lets the first `initial` messages from a call site
+// through, then only every `thereafter`th one, resetting the count once
+// `window` has elapsed since the window started.
+type TieredSampler struct {
+	initial    uint64
+	thereafter uint64
+	window     time.Duration
+
+	mu    sync.Mutex
+	state map[uint64]*tieredWindow
+}
+
+func NewTieredSampler(initial, thereafter int, window time.Duration) *TieredSampler {
+	return &TieredSampler{
+		initial:    uint64(initial),
+		thereafter: uint64(thereafter),
+		window:     window,
+		state:      make(map[uint64]*tieredWindow),
+	}
+}
+
+func (s *TieredSampler) Allow(level int, msg []byte) bool {
+	key := sampleKey(msg)
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	win, ok := s.state[key]
+	if !ok || now.Sub(win.start) >= s.window {
+		win = &tieredWindow{start: now}
+		s.state[key] = win
+	}
+
+	win.count++
+
+	if win.count <= s.initial {
+		return true
+	}
+	if s.thereafter == 0 {
+		return false
+	}
+
+	return (win.count-s.initial)%s.thereafter == 0
+}
+
+func sampleKey(msg []byte) uint64 {
+	key := msg
+	if loc := samplerCallerRe.Find(msg); loc != nil {
+		key = loc
+	}
+
+	h := fnv.New64a()
+	h.Write(key)
+	return h.Sum64()
+}
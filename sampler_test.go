@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateSamplerAllowsWithinBurst(t *testing.T) {
+	s := NewRateSampler(1, 3)
+
+	for i := 0; i < 3; i++ {
+		if !s.Allow(LEVEL_INFO, []byte("msg")) {
+			t.Fatalf("expected burst message %d to be allowed", i)
+		}
+	}
+
+	if s.Allow(LEVEL_INFO, []byte("msg")) {
+		t.Error("expected the burst to be exhausted")
+	}
+}
+
+func TestRateSamplerRefillsOverTime(t *testing.T) {
+	s := NewRateSampler(100, 1)
+
+	if !s.Allow(LEVEL_INFO, []byte("msg")) {
+		t.Fatal("expected the first message to be allowed")
+	}
+	if s.Allow(LEVEL_INFO, []byte("msg")) {
+		t.Fatal("expected the burst of 1 to be exhausted immediately")
+	}
+
+	time.Sleep(20 * time.Millisecond) // ~2 tokens at 100/s
+
+	if !s.Allow(LEVEL_INFO, []byte("msg")) {
+		t.Error("expected a refilled token to allow another message")
+	}
+}
+
+func TestTieredSamplerLetsInitialThrough(t *testing.T) {
+	s := NewTieredSampler(2, 0, time.Minute)
+
+	msg := []byte("ERROR: host sampler_test.go:1: boom")
+	if !s.Allow(LEVEL_ERROR, msg) {
+		t.Error("expected message 1 to be allowed")
+	}
+	if !s.Allow(LEVEL_ERROR, msg) {
+		t.Error("expected message 2 (still within initial) to be allowed")
+	}
+	if s.Allow(LEVEL_ERROR, msg) {
+		t.Error("expected message 3 to be suppressed (thereafter=0)")
+	}
+}
+
+func TestTieredSamplerThereafterRate(t *testing.T) {
+	s := NewTieredSampler(1, 3, time.Minute)
+	msg := []byte("ERROR: host sampler_test.go:1: boom")
+
+	var allowed int
+	for i := 0; i < 7; i++ {
+		if s.Allow(LEVEL_ERROR, msg) {
+			allowed++
+		}
+	}
+
+	// 1 initial + every 3rd of the remaining 6 (msgs 4 and 7) = 3.
+	if allowed != 3 {
+		t.Errorf("expected 3 allowed out of 7, got %d", allowed)
+	}
+}
+
+func TestTieredSamplerDistinguishesCallSites(t *testing.T) {
+	s := NewTieredSampler(1, 0, time.Minute)
+
+	msgA := []byte("ERROR: host a.go:1: boom")
+	msgB := []byte("ERROR: host b.go:2: boom")
+
+	if !s.Allow(LEVEL_ERROR, msgA) {
+		t.Error("expected first message from a.go to be allowed")
+	}
+	if !s.Allow(LEVEL_ERROR, msgB) {
+		t.Error("expected first message from b.go to be allowed despite a.go's quota being spent")
+	}
+	if s.Allow(LEVEL_ERROR, msgA) {
+		t.Error("expected second message from a.go to be suppressed")
+	}
+}
+
+func TestTieredSamplerResetsAfterWindow(t *testing.T) {
+	s := NewTieredSampler(1, 0, 10*time.Millisecond)
+	msg := []byte("ERROR: host sampler_test.go:1: boom")
+
+	if !s.Allow(LEVEL_ERROR, msg) {
+		t.Fatal("expected the first message to be allowed")
+	}
+	if s.Allow(LEVEL_ERROR, msg) {
+		t.Fatal("expected the second message within the window to be suppressed")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if !s.Allow(LEVEL_ERROR, msg) {
+		t.Error("expected the window to have reset, allowing another message")
+	}
+}
+
+func TestLoggerSuppressUpdatesStats(t *testing.T) {
+	provider := &recordingProvider{id: "rec"}
+	l := NewLogger()
+	l.SetLevel(LEVEL_INFO)
+	l.RegisterProvider(provider)
+	l.AddLogProvider(provider.GetID())
+	l.SetSampler(NewTieredSampler(1, 0, time.Minute))
+
+	// Both calls must come from the same source line so the
+	// TieredSampler's call-site key (parsed out of the formatted
+	// message) matches between them.
+	for i := 0; i < 2; i++ {
+		l.Log("repeated message")
+	}
+
+	if len(provider.logged) != 1 {
+		t.Errorf("expected only the first message to reach the provider, got %d", len(provider.logged))
+	}
+
+	if got := l.Stats().Suppressed; got != 1 {
+		t.Errorf("expected Stats().Suppressed == 1, got %d", got)
+	}
+}
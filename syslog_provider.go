@@ -0,0 +1,189 @@
+//go:build !windows
+// +build !windows
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/syslog"
+	"net"
+	"os"
+	"time"
+)
+
+const PROVIDER_SYSLOG = "syslog"
+
+// syslogMaxRetries bounds the exponential backoff applied to a single
+// message before it is given up on, mirroring telegramMaxRetries.
+const syslogMaxRetries = 5
+
+// SyslogProvider forwards messages to a syslog daemon, framed per
+// RFC5424 (log/syslog only speaks the older RFC3164 wire format, so
+// this dials the socket directly instead of using syslog.Writer). It is
+// an AsyncProvider: sends happen on a single background worker, and a
+// write that fails (a UDP socket going away, a restarting daemon) is
+// retried with backoff rather than dropped outright.
+type SyslogProvider struct {
+	*AsyncProvider
+	levelFilter
+}
+
+type syslogSender struct {
+	conn     net.Conn
+	facility syslog.Priority
+	tag      string
+	hostname string
+	pid      int
+	network  string
+	raddr    string
+	ctx      context.Context
+}
+
+// NewSyslogProvider dials network/raddr (e.g. "udp", "syslog.internal:514")
+// or, when both are empty, the first of the usual local syslog sockets.
+// facility is one of the syslog.LOG_* facility constants; tag identifies
+// this process in each framed message.
+func NewSyslogProvider(network, raddr string, facility syslog.Priority, tag string) (*SyslogProvider, error) {
+	conn, err := dialSyslog(network, raddr)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+
+	sender := &syslogSender{
+		conn:     conn,
+		facility: facility,
+		tag:      tag,
+		hostname: hostname,
+		pid:      os.Getpid(),
+		network:  network,
+		raddr:    raddr,
+	}
+
+	async := NewAsyncProvider(sender, 0)
+	sender.ctx = async.Context()
+
+	return &SyslogProvider{AsyncProvider: async, levelFilter: newLevelFilter()}, nil
+}
+
+func dialSyslog(network, raddr string) (net.Conn, error) {
+	if network != "" {
+		return net.Dial(network, raddr)
+	}
+
+	for _, path := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+		if conn, err := net.Dial("unixgram", path); err == nil {
+			return conn, nil
+		}
+	}
+
+	return nil, errors.New("logger: no local syslog socket found")
+}
+
+func (p *SyslogProvider) Log(msg []byte) {
+	if p.allow(LEVEL_INFO) {
+		p.AsyncProvider.Log(msg)
+	}
+}
+
+func (p *SyslogProvider) Error(msg []byte) {
+	if p.allow(LEVEL_ERROR) {
+		p.AsyncProvider.Error(msg)
+	}
+}
+
+func (p *SyslogProvider) Fatal(msg []byte) {
+	if p.allow(LEVEL_ERROR) {
+		p.AsyncProvider.Fatal(msg)
+	}
+}
+
+func (p *SyslogProvider) Debug(msg []byte) {
+	if p.allow(LEVEL_DEBUG) {
+		p.AsyncProvider.Debug(msg)
+	}
+}
+
+func (s *syslogSender) GetID() string {
+	return PROVIDER_SYSLOG
+}
+
+func (s *syslogSender) Log(msg []byte)   { s.deliver(context.Background(), syslog.LOG_INFO, msg) }
+func (s *syslogSender) Error(msg []byte) { s.deliver(context.Background(), syslog.LOG_ERR, msg) }
+func (s *syslogSender) Fatal(msg []byte) { s.deliver(context.Background(), syslog.LOG_CRIT, msg) }
+func (s *syslogSender) Debug(msg []byte) { s.deliver(context.Background(), syslog.LOG_DEBUG, msg) }
+
+// LogCtx, ErrorCtx, FatalCtx and DebugCtx are the ContextProvider
+// counterparts of Log/Error/Fatal/Debug: ctx is watched alongside the
+// sender's own shutdown context for the whole retry/backoff loop, so a
+// write already in flight when ctx is cancelled gives up instead of
+// running out its remaining attempts.
+func (s *syslogSender) LogCtx(ctx context.Context, msg []byte) {
+	s.deliver(ctx, syslog.LOG_INFO, msg)
+}
+
+func (s *syslogSender) ErrorCtx(ctx context.Context, msg []byte) {
+	s.deliver(ctx, syslog.LOG_ERR, msg)
+}
+
+func (s *syslogSender) FatalCtx(ctx context.Context, msg []byte) {
+	s.deliver(ctx, syslog.LOG_CRIT, msg)
+}
+
+func (s *syslogSender) DebugCtx(ctx context.Context, msg []byte) {
+	s.deliver(ctx, syslog.LOG_DEBUG, msg)
+}
+
+// deliver frames msg as an RFC5424 syslog message:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+//
+// and writes it with retry, so the worker goroutine doesn't drop a
+// message over a single transient socket error.
+func (s *syslogSender) deliver(ctx context.Context, severity syslog.Priority, msg []byte) {
+	pri := int(s.facility) | int(severity)
+	frame := []byte(fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		pri, time.Now().Format(time.RFC3339), s.hostname, s.tag, s.pid, msg))
+
+	s.writeWithRetry(ctx, frame)
+}
+
+func (s *syslogSender) writeWithRetry(ctx context.Context, frame []byte) {
+	backoff := 200 * time.Millisecond
+
+	for attempt := 0; ; attempt++ {
+		if _, err := s.conn.Write(frame); err == nil || attempt >= syslogMaxRetries {
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		backoff *= 2
+		s.reconnect()
+	}
+}
+
+// reconnect redials the syslog socket after a failed write. It leaves
+// the existing connection in place if redialing fails, so the next
+// attempt simply retries the dial.
+func (s *syslogSender) reconnect() {
+	conn, err := dialSyslog(s.network, s.raddr)
+	if err != nil {
+		return
+	}
+
+	s.conn.Close()
+	s.conn = conn
+}
@@ -2,15 +2,34 @@ package logger
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 )
 
 const PROVIDER_TELEGRAM = "telegram"
 
+// telegramMaxRetries bounds the exponential backoff applied to a single
+// message before it is given up on.
+const telegramMaxRetries = 5
+
+// TelegramProvider delivers messages to one or more Telegram chats. It
+// is an AsyncProvider: sends happen on a single background worker so a
+// slow or unreachable API never blocks the caller, and a bounded queue
+// provides backpressure instead of spawning a goroutine per message.
 type TelegramProvider struct {
+	*AsyncProvider
+}
+
+type telegramSender struct {
 	url     string
 	chatIds []string
+	client  *http.Client
+	ctx     context.Context
 }
 
 func NewTelegramProvider(url string, chatIds []string) (*TelegramProvider, error) {
@@ -21,47 +40,118 @@ func NewTelegramProvider(url string, chatIds []string) (*TelegramProvider, error
 		return nil, errors.New("Empty telegram chat ids.")
 	}
 
-	provider := &TelegramProvider{
+	sender := &telegramSender{
 		url:     url,
 		chatIds: chatIds,
+		client:  &http.Client{Timeout: 10 * time.Second},
 	}
 
-	return provider, nil
+	async := NewAsyncProvider(sender, 0)
+	sender.ctx = async.Context()
+
+	return &TelegramProvider{AsyncProvider: async}, nil
 }
 
-func (p TelegramProvider) GetID() string {
+func (s *telegramSender) GetID() string {
 	return PROVIDER_TELEGRAM
 }
 
-func (p TelegramProvider) Log(msg []byte) {
-	p.send("Log message\n", msg)
+func (s *telegramSender) Log(msg []byte) {
+	s.send(context.Background(), "Log message\n", msg)
+}
+
+func (s *telegramSender) Error(msg []byte) {
+	s.send(context.Background(), "Error message\n", msg)
+}
+
+func (s *telegramSender) Fatal(msg []byte) {
+	s.send(context.Background(), "Fatal message\n", msg)
+}
+
+func (s *telegramSender) Debug(msg []byte) {
+	s.send(context.Background(), "Debug message\n", msg)
 }
 
-func (p TelegramProvider) Error(msg []byte) {
-	p.send("Error message\n", msg)
+// LogCtx, ErrorCtx, FatalCtx and DebugCtx are the ContextProvider
+// counterparts of Log/Error/Fatal/Debug: ctx is watched alongside the
+// sender's own shutdown context for the whole retry/backoff loop, so a
+// send already in flight when ctx is cancelled gives up instead of
+// running out its remaining attempts.
+func (s *telegramSender) LogCtx(ctx context.Context, msg []byte) {
+	s.send(ctx, "Log message\n", msg)
 }
 
-func (p TelegramProvider) Fatal(msg []byte) {
-	p.send("Fatal message\n", msg)
+func (s *telegramSender) ErrorCtx(ctx context.Context, msg []byte) {
+	s.send(ctx, "Error message\n", msg)
 }
 
-func (p TelegramProvider) Debug(msg []byte) {
-	p.send("Debug message\n", msg)
+func (s *telegramSender) FatalCtx(ctx context.Context, msg []byte) {
+	s.send(ctx, "Fatal message\n", msg)
 }
 
-func (p TelegramProvider) send(subject string, body []byte) {
-	go tg_send(p.url, p.chatIds, subject, body)
+func (s *telegramSender) DebugCtx(ctx context.Context, msg []byte) {
+	s.send(ctx, "Debug message\n", msg)
 }
 
-func tg_send(url string, chatIds []string, subject string, body []byte) {
-	for _, chatId := range chatIds {
-		msg := "{\"chat_id\":" + chatId + ",\"text\":" + "\"" + subject + string(body) + "\"" + "}"
-		var jsonStr = []byte(msg)
-		req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonStr))
-		req.Header.Set("Content-Type", "application/json")
+type telegramPayload struct {
+	ChatID json.RawMessage `json:"chat_id"`
+	Text   string          `json:"text"`
+}
 
-		client := &http.Client{}
-		resp, _ := client.Do(req)
-		defer resp.Body.Close()
+func (s *telegramSender) send(ctx context.Context, subject string, body []byte) {
+	text := subject + string(body)
+
+	for _, chatId := range s.chatIds {
+		payload, err := json.Marshal(telegramPayload{ChatID: json.RawMessage(chatId), Text: text})
+		if err != nil {
+			continue
+		}
+
+		s.sendWithRetry(ctx, payload)
 	}
 }
+
+// sendWithRetry watches both s.ctx (the provider's own shutdown,
+// cancelled by AsyncProvider.Close) and ctx (the message's own
+// request-scoped context, if any) for the whole backoff loop, so
+// either one aborts a retry that's already under way.
+func (s *telegramSender) sendWithRetry(ctx context.Context, payload []byte) {
+	backoff := 200 * time.Millisecond
+
+	for attempt := 0; ; attempt++ {
+		if err := s.post(payload); err == nil || attempt >= telegramMaxRetries {
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		backoff *= 2
+	}
+}
+
+func (s *telegramSender) post(payload []byte) error {
+	req, err := http.NewRequest("POST", s.url, bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("telegram: unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}